@@ -0,0 +1,160 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// specCache persists downloaded spec bodies plus their ETag/Last-Modified
+// validators under $XDG_CACHE_HOME/krakend-contract/, so re-running the
+// tool issues conditional requests instead of redownloading every spec.
+type specCache struct {
+	dir string
+}
+
+// cacheEntry is the on-disk representation of one cached spec, keyed by URL.
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Body         []byte `json:"body"`
+}
+
+// cacheRootDir resolves $XDG_CACHE_HOME/krakend-contract/<subdir>, creating
+// it if necessary. Shared by specCache and k8sSpecCache so both caches live
+// under the same root.
+func cacheRootDir(subdir string) (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		userCache, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("resolve cache dir: %w", err)
+		}
+		base = userCache
+	}
+
+	dir := filepath.Join(base, "krakend-contract", subdir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create cache dir: %w", err)
+	}
+	return dir, nil
+}
+
+// newSpecCache resolves the cache directory and ensures it exists.
+func newSpecCache() (*specCache, error) {
+	dir, err := cacheRootDir("")
+	if err != nil {
+		return nil, err
+	}
+	return &specCache{dir: dir}, nil
+}
+
+func (c *specCache) pathFor(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *specCache) load(url string) (*cacheEntry, bool) {
+	data, err := os.ReadFile(c.pathFor(url))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+func (c *specCache) save(entry *cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal cache entry: %w", err)
+	}
+	return os.WriteFile(c.pathFor(entry.URL), data, 0o644)
+}
+
+// fetchURL downloads specURL, revalidating against the cache with
+// If-None-Match/If-Modified-Since when a prior entry exists. A 304 response
+// or a network error with a cached entry available both return the cached
+// body, the latter letting the tool work offline against the last-known-good
+// spec. cache may be nil, in which case it always fetches fresh.
+func fetchURL(cache *specCache, specURL string, verbose bool) ([]byte, error) {
+	if cache == nil {
+		return fetchURLUncached(specURL)
+	}
+
+	entry, cached := cache.load(specURL)
+
+	req, err := http.NewRequest(http.MethodGet, specURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	if cached {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if cached {
+			if verbose {
+				fmt.Printf("    %s: offline, serving from cache (%v)\n", specURL, err)
+			}
+			return entry.Body, nil
+		}
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		if verbose {
+			fmt.Printf("    %s: not modified, serving from cache\n", specURL)
+		}
+		return entry.Body, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, specURL)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+
+	if err := cache.save(&cacheEntry{
+		URL:          specURL,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Body:         body,
+	}); err != nil && verbose {
+		fmt.Printf("    %s: failed to write cache: %v\n", specURL, err)
+	}
+
+	return body, nil
+}
+
+func fetchURLUncached(specURL string) ([]byte, error) {
+	resp, err := http.Get(specURL)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, specURL)
+	}
+
+	return io.ReadAll(resp.Body)
+}
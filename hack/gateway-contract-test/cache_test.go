@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchURLCachesAndRevalidates(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("spec body"))
+	}))
+	defer srv.Close()
+
+	cache := &specCache{dir: t.TempDir()}
+
+	body, err := fetchURL(cache, srv.URL, false)
+	if err != nil {
+		t.Fatalf("fetchURL (fresh): %v", err)
+	}
+	if string(body) != "spec body" {
+		t.Fatalf("expected the fresh body, got %q", body)
+	}
+
+	body, err = fetchURL(cache, srv.URL, false)
+	if err != nil {
+		t.Fatalf("fetchURL (revalidate): %v", err)
+	}
+	if string(body) != "spec body" {
+		t.Fatalf("expected the cached body after a 304, got %q", body)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests (one fresh, one revalidated), got %d", requests)
+	}
+}
+
+func TestFetchURLServesStaleCacheOnNetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("spec body"))
+	}))
+	cache := &specCache{dir: t.TempDir()}
+	if _, err := fetchURL(cache, srv.URL, false); err != nil {
+		t.Fatalf("fetchURL (fresh): %v", err)
+	}
+	srv.Close()
+
+	body, err := fetchURL(cache, srv.URL, false)
+	if err != nil {
+		t.Fatalf("expected the cached body when the server is unreachable, got error: %v", err)
+	}
+	if string(body) != "spec body" {
+		t.Fatalf("expected the cached body, got %q", body)
+	}
+}
+
+func TestFetchURLFailsOnServerErrorDespiteCache(t *testing.T) {
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("spec body"))
+	}))
+	defer srv.Close()
+
+	cache := &specCache{dir: t.TempDir()}
+	if _, err := fetchURL(cache, srv.URL, false); err != nil {
+		t.Fatalf("fetchURL (fresh): %v", err)
+	}
+
+	fail = true
+	if _, err := fetchURL(cache, srv.URL, false); err == nil {
+		t.Fatal("expected a 500 response to be a real failure, not a silent fallback to the stale cache")
+	}
+}
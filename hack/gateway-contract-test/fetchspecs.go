@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// activeCache is the spec cache used by downloadAndParseSpec and the
+// swagger2/asyncapi loaders; nil disables caching.
+var activeCache *specCache
+
+// loadSpecsConcurrently resolves every (non-filtered) entry in
+// contractSpecs at once, bounded by concurrency, instead of the one-at-a-
+// time download loop this replaced. Output lines are still printed in a
+// stable, sorted-by-name order so a run is reproducible to read.
+func loadSpecsConcurrently(ctx context.Context, contractSpecs map[string]ContractSpec, serviceFilter, overrideHost, overridePath string, concurrency int, verbose bool) (map[string]*Spec, error) {
+	names := make([]string, 0, len(contractSpecs))
+	for name := range contractSpecs {
+		if serviceFilter != "" && name != serviceFilter {
+			if verbose {
+				fmt.Printf("  %s: skipped (filtering for %s)\n", name, serviceFilter)
+			}
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if concurrency <= 0 {
+		concurrency = len(names)
+		if concurrency > 8 {
+			concurrency = 8
+		}
+		if concurrency == 0 {
+			concurrency = 1
+		}
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+
+	specs := make(map[string]*Spec, len(names))
+	lines := make(map[string]string, len(names))
+	var mu sync.Mutex
+
+	for _, name := range names {
+		name := name
+		spec := contractSpecs[name]
+		g.Go(func() error {
+			var s *Spec
+			var err error
+			if overrideHost == name {
+				fmt.Printf("  %s: loading from local file %s\n", name, overridePath)
+				s, err = loadAndParseSpec(overridePath, verbose)
+			} else {
+				var loader SpecLoader
+				loader, err = specLoaderFor(spec.Type)
+				if err == nil {
+					s, err = loader.Load(gctx, spec, verbose)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				lines[name] = fmt.Sprintf("  %s: FAILED (%v)", name, err)
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			lines[name] = fmt.Sprintf("  %s: OK (%d operations)", name, s.operationCount())
+			specs[name] = s
+			return nil
+		})
+	}
+
+	waitErr := g.Wait()
+
+	// Flush buffered OK/FAILED lines in the same sorted-by-name order
+	// dispatch used, since goroutine completion order isn't deterministic.
+	for _, name := range names {
+		if line, ok := lines[name]; ok {
+			if specs[name] == nil {
+				fmt.Fprintln(os.Stderr, line)
+			} else {
+				fmt.Println(line)
+			}
+		}
+	}
+
+	if waitErr != nil {
+		return nil, waitErr
+	}
+	return specs, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+const loadSpecsFixtureDoc = `
+openapi: "3.0.0"
+info:
+  title: T
+  version: "1"
+paths:
+  /items:
+    get:
+      operationId: listItems
+      responses:
+        "200":
+          description: ok
+`
+
+func TestLoadSpecsConcurrentlyResolvesAll(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(loadSpecsFixtureDoc))
+	}))
+	defer srv.Close()
+
+	contractSpecs := map[string]ContractSpec{
+		"users.internal":  {Type: "openapi3", URL: srv.URL},
+		"orders.internal": {Type: "openapi3", URL: srv.URL},
+	}
+
+	specs, err := loadSpecsConcurrently(context.Background(), contractSpecs, "", "", "", 0, false)
+	if err != nil {
+		t.Fatalf("loadSpecsConcurrently: %v", err)
+	}
+	if len(specs) != 2 {
+		t.Fatalf("expected both specs to resolve, got %d: %v", len(specs), specs)
+	}
+}
+
+func TestLoadSpecsConcurrentlyServiceFilter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(loadSpecsFixtureDoc))
+	}))
+	defer srv.Close()
+
+	contractSpecs := map[string]ContractSpec{
+		"users.internal":  {Type: "openapi3", URL: srv.URL},
+		"orders.internal": {Type: "openapi3", URL: srv.URL},
+	}
+
+	specs, err := loadSpecsConcurrently(context.Background(), contractSpecs, "users.internal", "", "", 0, false)
+	if err != nil {
+		t.Fatalf("loadSpecsConcurrently: %v", err)
+	}
+	if len(specs) != 1 || specs["users.internal"] == nil {
+		t.Fatalf("expected only the filtered service to resolve, got %v", specs)
+	}
+}
+
+func TestLoadSpecsConcurrentlyOverride(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "spec-*.yaml")
+	if err != nil {
+		t.Fatalf("create temp file: %v", err)
+	}
+	if _, err := f.WriteString(loadSpecsFixtureDoc); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	f.Close()
+
+	contractSpecs := map[string]ContractSpec{
+		"users.internal": {Type: "openapi3", URL: "http://unreachable.invalid"},
+	}
+
+	specs, err := loadSpecsConcurrently(context.Background(), contractSpecs, "", "users.internal", f.Name(), 0, false)
+	if err != nil {
+		t.Fatalf("expected the override to bypass the unreachable URL, got error: %v", err)
+	}
+	if specs["users.internal"] == nil {
+		t.Fatalf("expected the overridden spec to resolve from the local file, got %v", specs)
+	}
+}
+
+func TestLoadSpecsConcurrentlyFailurePropagates(t *testing.T) {
+	contractSpecs := map[string]ContractSpec{
+		"broken.internal": {Type: "not-a-real-type"},
+	}
+
+	if _, err := loadSpecsConcurrently(context.Background(), contractSpecs, "", "", "", 0, false); err == nil {
+		t.Fatal("expected an unknown spec type to fail the whole load, got nil")
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// openAPISpecURLAnnotation lets a Service point the tool at its OpenAPI
+// document directly (e.g. one served by an API gateway sidecar) instead of
+// relying on the well-known /openapi.yaml path on one of its pods.
+const openAPISpecURLAnnotation = "openapi.spec/url"
+
+// k8sSpecCache persists the resolved operation set for each Service under
+// $XDG_CACHE_HOME/krakend-contract/k8s-services/, keyed by namespace/name,
+// so re-runs against an unchanged cluster skip the annotation/ConfigMap/
+// port-forward resolution entirely instead of redoing it every time.
+type k8sSpecCache struct {
+	dir string
+}
+
+// k8sCacheEntry is the on-disk representation of one cached Service's
+// resolved spec: its ResourceVersion at resolution time plus the indexed
+// "METHOD path" keys needed to reconstruct a synthetic Spec.
+type k8sCacheEntry struct {
+	ResourceVersion string   `json:"resource_version"`
+	Ops             []string `json:"ops"`
+}
+
+func newK8sSpecCache() (*k8sSpecCache, error) {
+	dir, err := cacheRootDir("k8s-services")
+	if err != nil {
+		return nil, err
+	}
+	return &k8sSpecCache{dir: dir}, nil
+}
+
+func (c *k8sSpecCache) pathFor(namespace, name string) string {
+	return filepath.Join(c.dir, namespace+"_"+name+".json")
+}
+
+// load and save are no-ops on a nil cache, so discovery still works when
+// newK8sSpecCache failed to resolve a cache directory.
+
+func (c *k8sSpecCache) load(namespace, name string) (*k8sCacheEntry, bool) {
+	if c == nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.pathFor(namespace, name))
+	if err != nil {
+		return nil, false
+	}
+	var entry k8sCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+func (c *k8sSpecCache) save(namespace, name string, entry k8sCacheEntry) error {
+	if c == nil {
+		return nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal k8s cache entry: %w", err)
+	}
+	return os.WriteFile(c.pathFor(namespace, name), data, 0o644)
+}
+
+// newK8sClientset builds a client from KUBECONFIG (or the in-cluster config
+// when running as a pod), matching how other DCM tooling talks to clusters.
+func newK8sClientset() (*kubernetes.Clientset, *rest.Config, error) {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		kubeconfig := os.Getenv("KUBECONFIG")
+		if kubeconfig == "" {
+			home, herr := os.UserHomeDir()
+			if herr != nil {
+				return nil, nil, fmt.Errorf("resolve kubeconfig: %w", err)
+			}
+			kubeconfig = filepath.Join(home, ".kube", "config")
+		}
+		cfg, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load kubeconfig: %w", err)
+		}
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("build clientset: %w", err)
+	}
+	return clientset, cfg, nil
+}
+
+// firstDNSLabel returns the leading label of a DNS name, e.g. "billing" for
+// both the bare in-namespace name "billing" and the cross-namespace form
+// "billing.prod.svc.cluster.local" krakend.json backends normally use.
+func firstDNSLabel(host string) string {
+	if i := strings.IndexByte(host, '.'); i >= 0 {
+		return host[:i]
+	}
+	return host
+}
+
+// discoverK8sSpecs finds, for each backend hostname, the matching in-cluster
+// Service (matched on the hostname's leading DNS label against the Service
+// name) and resolves its OpenAPI spec, either from the "openapi.spec/url"
+// annotation or a ConfigMap named "<service>-openapi", falling back to
+// /openapi.yaml on one of the Service's pods via port-forward. Results are
+// cached by the Service's ResourceVersion. serviceFilter restricts discovery
+// to a single hostname (mirroring -service for x-contract-specs); overrideHost
+// substitutes a local file for that hostname instead of discovering it.
+func discoverK8sSpecs(ctx context.Context, namespace, selector string, hostnames []string, serviceFilter, overrideHost, overridePath string, cache *k8sSpecCache, verbose bool) (map[string]*Spec, error) {
+	clientset, restConfig, err := newK8sClientset()
+	if err != nil {
+		return nil, err
+	}
+
+	// wanted maps a Service's leading DNS label to the full hostname used by
+	// backend routes, so results can be keyed the same way x-contract-specs
+	// entries are.
+	wanted := make(map[string]string, len(hostnames))
+	for _, h := range hostnames {
+		if serviceFilter != "" && h != serviceFilter {
+			if verbose {
+				fmt.Printf("  %s: skipped (filtering for %s)\n", h, serviceFilter)
+			}
+			continue
+		}
+		wanted[firstDNSLabel(h)] = h
+	}
+
+	svcList, err := clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+
+	specs := make(map[string]*Spec, len(wanted))
+	for _, svc := range svcList.Items {
+		hostname, ok := wanted[svc.Name]
+		if !ok {
+			continue
+		}
+
+		if overrideHost == hostname {
+			fmt.Printf("  %s: loading from local file %s\n", hostname, overridePath)
+			spec, err := loadAndParseSpec(overridePath, verbose)
+			if err != nil {
+				return nil, fmt.Errorf("resolve spec for service %s: %w", svc.Name, err)
+			}
+			specs[hostname] = spec
+			continue
+		}
+
+		if entry, ok := cache.load(svc.Namespace, svc.Name); ok && entry.ResourceVersion == svc.ResourceVersion {
+			if verbose {
+				fmt.Printf("  %s: cached (unchanged, resourceVersion=%s)\n", svc.Name, svc.ResourceVersion)
+			}
+			specs[hostname] = newSyntheticSpec(entry.Ops)
+			continue
+		}
+
+		spec, err := resolveServiceSpec(ctx, clientset, restConfig, &svc, verbose)
+		if err != nil {
+			return nil, fmt.Errorf("resolve spec for service %s: %w", svc.Name, err)
+		}
+		if err := cache.save(svc.Namespace, svc.Name, k8sCacheEntry{ResourceVersion: svc.ResourceVersion, Ops: spec.opKeys()}); err != nil && verbose {
+			fmt.Printf("  %s: failed to write cache: %v\n", svc.Name, err)
+		}
+		specs[hostname] = spec
+	}
+
+	return specs, nil
+}
+
+func resolveServiceSpec(ctx context.Context, clientset *kubernetes.Clientset, restConfig *rest.Config, svc *corev1.Service, verbose bool) (*Spec, error) {
+	if specURL := svc.Annotations[openAPISpecURLAnnotation]; specURL != "" {
+		if verbose {
+			fmt.Printf("  %s: fetching spec from annotation %s\n", svc.Name, specURL)
+		}
+		return downloadAndParseSpec(specURL, verbose)
+	}
+
+	cmName := svc.Name + "-openapi"
+	cm, err := clientset.CoreV1().ConfigMaps(svc.Namespace).Get(ctx, cmName, metav1.GetOptions{})
+	if err == nil {
+		for _, key := range []string{"openapi.yaml", "openapi.json", "spec"} {
+			if data, ok := cm.Data[key]; ok {
+				if verbose {
+					fmt.Printf("  %s: loading spec from configmap %s/%s\n", svc.Name, cmName, key)
+				}
+				return parseSpec([]byte(data), verbose)
+			}
+		}
+	}
+
+	if verbose {
+		fmt.Printf("  %s: fetching /openapi.yaml via port-forward\n", svc.Name)
+	}
+	return fetchSpecViaPortForward(restConfig, clientset, svc, verbose)
+}
+
+// fetchSpecViaPortForward port-forwards to the first running pod behind svc
+// and GETs /openapi.yaml, for services that don't advertise their spec via
+// annotation or ConfigMap.
+func fetchSpecViaPortForward(restConfig *rest.Config, clientset *kubernetes.Clientset, svc *corev1.Service, verbose bool) (*Spec, error) {
+	if len(svc.Spec.Selector) == 0 {
+		return nil, fmt.Errorf("service %s has no selector, can't find a pod to port-forward to", svc.Name)
+	}
+
+	ctx := context.Background()
+	pods, err := clientset.CoreV1().Pods(svc.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: svc.Spec.Selector}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list pods for service %s: %w", svc.Name, err)
+	}
+
+	var podName string
+	for _, p := range pods.Items {
+		if p.Status.Phase == corev1.PodRunning {
+			podName = p.Name
+			break
+		}
+	}
+	if podName == "" {
+		return nil, fmt.Errorf("no running pod found for service %s", svc.Name)
+	}
+
+	targetPort := 80
+	if len(svc.Spec.Ports) > 0 && svc.Spec.Ports[0].TargetPort.IntValue() > 0 {
+		targetPort = svc.Spec.Ports[0].TargetPort.IntValue()
+	}
+
+	localPort, stopCh, err := startPortForward(restConfig, clientset, svc.Namespace, podName, targetPort)
+	if err != nil {
+		return nil, err
+	}
+	defer close(stopCh)
+
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/openapi.yaml", localPort))
+	if err != nil {
+		return nil, fmt.Errorf("fetch /openapi.yaml from pod %s: %w", podName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d fetching /openapi.yaml from pod %s", resp.StatusCode, podName)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read /openapi.yaml body: %w", err)
+	}
+
+	return parseSpec(body, verbose)
+}
+
+func startPortForward(restConfig *rest.Config, clientset *kubernetes.Clientset, namespace, podName string, targetPort int) (int, chan struct{}, error) {
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("portforward")
+
+	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
+	if err != nil {
+		return 0, nil, fmt.Errorf("build spdy round tripper: %w", err)
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	ports := []string{"0:" + strconv.Itoa(targetPort)}
+
+	fw, err := portforward.New(dialer, ports, stopCh, readyCh, io.Discard, io.Discard)
+	if err != nil {
+		return 0, nil, fmt.Errorf("create port-forwarder: %w", err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- fw.ForwardPorts() }()
+
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		return 0, nil, fmt.Errorf("port-forward to pod %s failed: %w", podName, err)
+	}
+
+	forwarded, err := fw.GetPorts()
+	if err != nil || len(forwarded) == 0 {
+		close(stopCh)
+		return 0, nil, fmt.Errorf("no forwarded port for pod %s: %w", podName, err)
+	}
+
+	return int(forwarded[0].Local), stopCh, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestFirstDNSLabel(t *testing.T) {
+	cases := []struct {
+		host string
+		want string
+	}{
+		{"billing", "billing"},
+		{"billing.prod.svc.cluster.local", "billing"},
+		{"billing.prod", "billing"},
+	}
+
+	for _, c := range cases {
+		if got := firstDNSLabel(c.host); got != c.want {
+			t.Errorf("firstDNSLabel(%q) = %q, want %q", c.host, got, c.want)
+		}
+	}
+}
+
+func TestK8sSpecCacheRoundTrip(t *testing.T) {
+	cache := &k8sSpecCache{dir: t.TempDir()}
+
+	if _, ok := cache.load("prod", "billing"); ok {
+		t.Fatal("expected no cache entry before save")
+	}
+
+	entry := k8sCacheEntry{ResourceVersion: "123", Ops: []string{"GET /v1/invoices"}}
+	if err := cache.save("prod", "billing", entry); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	got, ok := cache.load("prod", "billing")
+	if !ok {
+		t.Fatal("expected a cache entry after save")
+	}
+	if got.ResourceVersion != entry.ResourceVersion || len(got.Ops) != 1 || got.Ops[0] != entry.Ops[0] {
+		t.Errorf("loaded entry %+v does not match saved entry %+v", got, entry)
+	}
+}
+
+func TestK8sSpecCacheNilIsNoOp(t *testing.T) {
+	var cache *k8sSpecCache
+	if _, ok := cache.load("prod", "billing"); ok {
+		t.Fatal("expected a nil cache to always miss")
+	}
+	if err := cache.save("prod", "billing", k8sCacheEntry{}); err != nil {
+		t.Fatalf("expected saving to a nil cache to be a no-op, got error: %v", err)
+	}
+}
@@ -1,17 +1,14 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"os"
 	"regexp"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 // KrakenD config (partial)
@@ -32,18 +29,16 @@ type Backend struct {
 	Host       []string `json:"host"`
 }
 
+// ContractSpec describes where and how to load the contract for one
+// backend. Type selects the SpecLoader; it defaults to "openapi3" so
+// existing krakend.json configs keep working unchanged. URL is generic
+// because not every loader fetches an OpenAPI document with it: swagger2
+// points it at a Swagger 2.0 document and asyncapi at an AsyncAPI document.
 type ContractSpec struct {
-	OpenAPIURL string `json:"openapi_url"`
-}
-
-// OpenAPI spec (partial)
-type OpenAPISpec struct {
-	Servers []Server                          `yaml:"servers"`
-	Paths   map[string]map[string]interface{} `yaml:"paths"`
-}
-
-type Server struct {
-	URL string `yaml:"url"`
+	Type       string   `json:"type"`
+	URL        string   `json:"url"`
+	GRPCAddr   string   `json:"grpc_addr"`
+	ProtoFiles []string `json:"proto_files"`
 }
 
 // backendRoute is an extracted route from KrakenD config
@@ -67,6 +62,27 @@ func extractHostname(hostURL string) string {
 	return u.Hostname()
 }
 
+// backendHostnames returns the unique set of backend hostnames referenced by
+// cfg's endpoints, used to drive Kubernetes Service discovery.
+func backendHostnames(cfg KrakenDConfig) []string {
+	seen := make(map[string]bool)
+	var hostnames []string
+	for _, ep := range cfg.Endpoints {
+		for _, b := range ep.Backend {
+			if len(b.Host) == 0 {
+				continue
+			}
+			hostname := extractHostname(b.Host[0])
+			if hostname == "" || seen[hostname] {
+				continue
+			}
+			seen[hostname] = true
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return hostnames
+}
+
 func main() {
 	configPath := flag.String("config", "config/krakend.json", "path to krakend.json")
 	warnUncovered := flag.Bool("warn-uncovered", false, "warn about spec paths not covered by any backend route")
@@ -74,8 +90,30 @@ func main() {
 	verbose := flag.Bool("verbose", false, "verbose output")
 	override := flag.String("override", "", "override a service spec with local file: hostname=/path/to/spec.yaml")
 	service := flag.String("service", "", "only validate routes for this service hostname")
+	replay := flag.String("replay", "", "path to a HAR (.har) or JSONL file of recorded gateway traffic to validate instead of route-existence checks")
+	k8sNamespace := flag.String("k8s-namespace", "", "discover backend specs from Kubernetes Services in this namespace instead of x-contract-specs")
+	k8sSelector := flag.String("k8s-selector", "", "label selector used to narrow down Services when -k8s-namespace is set")
+	output := flag.String("output", "text", "result format: text, junit, sarif, or json")
+	pushGateway := flag.String("push-gateway", "", "Prometheus pushgateway URL to push krakend_contract_route_pass/_fail gauges to")
+	concurrency := flag.Int("concurrency", 0, "number of specs to fetch concurrently (default min(8, number of specs))")
+	noCache := flag.Bool("no-cache", false, "always redownload specs instead of revalidating against the on-disk cache")
 	flag.Parse()
 
+	if !*noCache {
+		cache, err := newSpecCache()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: spec cache disabled: %v\n", err)
+		} else {
+			activeCache = cache
+		}
+	}
+
+	reporter, err := newReporter(*output, os.Stdout, *configPath, *verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
 	// Parse override flag
 	var overrideHost, overridePath string
 	if *override != "" {
@@ -100,7 +138,7 @@ func main() {
 		os.Exit(2)
 	}
 
-	if len(cfg.ContractSpecs) == 0 {
+	if *k8sNamespace == "" && len(cfg.ContractSpecs) == 0 {
 		fmt.Fprintf(os.Stderr, "Error: no x-contract-specs found in config\n")
 		os.Exit(2)
 	}
@@ -109,36 +147,40 @@ func main() {
 	fmt.Println("========================================")
 
 	// Download and parse specs
-	fmt.Println("Downloading specs...")
-	specOps := make(map[string]map[string]bool)
-	specAllPaths := make(map[string]map[string]bool)
-
-	for name, spec := range cfg.ContractSpecs {
-		// If -service is set, skip hostnames that don't match
-		if *service != "" && name != *service {
-			if *verbose {
-				fmt.Printf("  %s: skipped (filtering for %s)\n", name, *service)
-			}
-			continue
+	var specs map[string]*Spec
+	if *k8sNamespace != "" {
+		fmt.Printf("Discovering specs from Kubernetes (namespace=%s, selector=%q)...\n", *k8sNamespace, *k8sSelector)
+		k8sCache, err := newK8sSpecCache()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: k8s spec cache disabled: %v\n", err)
 		}
-
-		var ops map[string]bool
-		var allPaths map[string]bool
-		var opCount int
-
-		if overrideHost == name {
-			fmt.Printf("  %s: loading from local file %s\n", name, overridePath)
-			ops, allPaths, opCount, err = loadAndParseSpec(overridePath, *verbose)
-		} else {
-			ops, allPaths, opCount, err = downloadAndParseSpec(spec.OpenAPIURL, *verbose)
+		specs, err = discoverK8sSpecs(context.Background(), *k8sNamespace, *k8sSelector, backendHostnames(cfg), *service, overrideHost, overridePath, k8sCache, *verbose)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error discovering specs: %v\n", err)
+			os.Exit(2)
+		}
+		for name, s := range specs {
+			fmt.Printf("  %s: OK (%d operations)\n", name, s.operationCount())
+		}
+	} else {
+		fmt.Println("Downloading specs...")
+		specs, err = loadSpecsConcurrently(context.Background(), cfg.ContractSpecs, *service, overrideHost, overridePath, *concurrency, *verbose)
+		if err != nil {
+			os.Exit(2)
 		}
+	}
+
+	if *replay != "" {
+		entries, err := loadReplayEntries(*replay)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "  %s: FAILED (%v)\n", name, err)
+			fmt.Fprintf(os.Stderr, "Error reading replay file: %v\n", err)
 			os.Exit(2)
 		}
-		fmt.Printf("  %s: OK (%d operations)\n", name, opCount)
-		specOps[name] = ops
-		specAllPaths[name] = allPaths
+
+		fmt.Printf("\nReplaying %d recorded requests against resolved operations...\n", len(entries))
+		results := validateReplay(specs, entries)
+		finish(reporter, *pushGateway, results, nil)
+		return
 	}
 
 	// Extract backend routes
@@ -178,139 +220,68 @@ func main() {
 	}
 	fmt.Printf("\nValidating %d backend routes%s...\n", len(routes), skipMsg)
 
-	passed := 0
-	failed := 0
-
 	// Track which spec paths are covered
 	coveredPaths := make(map[string]map[string]bool)
-	for name := range specOps {
+	for name := range specs {
 		coveredPaths[name] = make(map[string]bool)
 	}
 
+	var results []routeResult
 	for _, r := range routes {
-		ops, ok := specOps[r.hostname]
+		s, ok := specs[r.hostname]
 		if !ok {
-			fmt.Printf("  FAIL  %-6s %-45s -> %s\n", r.method, r.path, r.hostname)
-			fmt.Printf("        no spec configured for hostname %q\n", r.hostname)
-			failed++
+			results = append(results, routeResult{
+				Method: r.method, Path: r.path, Hostname: r.hostname,
+				Pass: false, Detail: fmt.Sprintf("no spec configured for hostname %q", r.hostname),
+			})
 			continue
 		}
 
 		normalizedPath := normalizePath(r.path)
 		key := r.method + " " + normalizedPath
-		if ops[key] {
-			fmt.Printf("  PASS  %-6s %-45s -> %s\n", r.method, r.path, r.hostname)
-			passed++
+		if _, ok := s.ops[key]; ok {
+			results = append(results, routeResult{Method: r.method, Path: r.path, Hostname: r.hostname, Pass: true})
 			coveredPaths[r.hostname][normalizedPath] = true
 		} else {
-			fmt.Printf("  FAIL  %-6s %-45s -> %s\n", r.method, r.path, r.hostname)
-			fmt.Printf("        not found in OpenAPI spec\n")
-			failed++
+			results = append(results, routeResult{
+				Method: r.method, Path: r.path, Hostname: r.hostname,
+				Pass: false, Detail: "not found in OpenAPI spec",
+			})
 		}
 	}
 
-	// Warn about uncovered spec paths
+	var uncovered []uncoveredPath
 	if *warnUncovered {
-		fmt.Println()
-		for name, paths := range specAllPaths {
+		for name, s := range specs {
 			covered := coveredPaths[name]
-			for path := range paths {
+			for path := range s.allPaths {
 				if !covered[path] {
-					fmt.Printf("  WARN  spec path %-45s in %s not covered by any gateway route\n", path, name)
+					uncovered = append(uncovered, uncoveredPath{Hostname: name, Path: path})
 				}
 			}
 		}
 	}
 
-	fmt.Println()
-	if failed > 0 {
-		fmt.Printf("Result: FAIL (%d passed, %d failed)\n", passed, failed)
-		os.Exit(1)
-	}
-	fmt.Printf("Result: PASS (%d passed, %d failed)\n", passed, failed)
+	finish(reporter, *pushGateway, results, uncovered)
 }
 
-// parseSpec parses OpenAPI YAML bytes and returns operations and paths.
-func parseSpec(data []byte, verbose bool) (map[string]bool, map[string]bool, int, error) {
-	var spec OpenAPISpec
-	if err := yaml.Unmarshal(data, &spec); err != nil {
-		return nil, nil, 0, fmt.Errorf("parse YAML: %w", err)
-	}
-
-	// Determine base path from servers[0].url
-	basePath := ""
-	if len(spec.Servers) > 0 {
-		serverURL := spec.Servers[0].URL
-		if strings.HasPrefix(serverURL, "http://") || strings.HasPrefix(serverURL, "https://") {
-			if u, err := url.Parse(serverURL); err == nil {
-				basePath = strings.TrimSuffix(u.Path, "/")
-			}
-		} else {
-			basePath = strings.TrimSuffix(serverURL, "/")
+// finish renders results through the selected Reporter, optionally pushes
+// them to a Prometheus pushgateway, and exits non-zero on any failure.
+func finish(reporter Reporter, pushGateway string, results []routeResult, uncovered []uncoveredPath) {
+	if pushGateway != "" {
+		if err := pushResults(pushGateway, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Error pushing to gateway: %v\n", err)
 		}
 	}
 
-	if verbose && basePath != "" {
-		fmt.Printf("    base path: %s\n", basePath)
-	}
-
-	httpMethods := map[string]bool{
-		"get": true, "post": true, "put": true, "patch": true,
-		"delete": true, "head": true, "options": true, "trace": true,
+	if err := reporter.Report(results, uncovered); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering report: %v\n", err)
+		os.Exit(2)
 	}
 
-	ops := make(map[string]bool)
-	allPaths := make(map[string]bool)
-	opCount := 0
-
-	for path, methods := range spec.Paths {
-		fullPath := basePath + path
-		normalizedPath := normalizePath(fullPath)
-		allPaths[normalizedPath] = true
-
-		for method := range methods {
-			if !httpMethods[strings.ToLower(method)] {
-				continue
-			}
-			key := strings.ToUpper(method) + " " + normalizedPath
-			ops[key] = true
-			opCount++
-
-			if verbose {
-				fmt.Printf("    spec: %s\n", key)
-			}
+	for _, r := range results {
+		if !r.Pass {
+			os.Exit(1)
 		}
 	}
-
-	return ops, allPaths, opCount, nil
-}
-
-// downloadAndParseSpec fetches an OpenAPI spec from a URL.
-func downloadAndParseSpec(specURL string, verbose bool) (map[string]bool, map[string]bool, int, error) {
-	resp, err := http.Get(specURL)
-	if err != nil {
-		return nil, nil, 0, fmt.Errorf("download failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, nil, 0, fmt.Errorf("HTTP %d from %s", resp.StatusCode, specURL)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, nil, 0, fmt.Errorf("read body: %w", err)
-	}
-
-	return parseSpec(body, verbose)
-}
-
-// loadAndParseSpec reads an OpenAPI spec from a local file.
-func loadAndParseSpec(path string, verbose bool) (map[string]bool, map[string]bool, int, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, nil, 0, fmt.Errorf("read file: %w", err)
-	}
-
-	return parseSpec(data, verbose)
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// pushResults emits krakend_contract_route_pass/_fail gauges, one pair per
+// route labelled by hostname, method and path, to the given Prometheus
+// pushgateway URL so contract test
+// runs show up alongside other CI metrics instead of only in build logs.
+func pushResults(gatewayURL string, routes []routeResult) error {
+	passGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "krakend_contract_route_pass",
+		Help: "1 if the backend route matched its OpenAPI contract, 0 otherwise",
+	}, []string{"hostname", "method", "path"})
+	failGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "krakend_contract_route_fail",
+		Help: "1 if the backend route failed its OpenAPI contract, 0 otherwise",
+	}, []string{"hostname", "method", "path"})
+
+	for _, rt := range routes {
+		labels := prometheus.Labels{"hostname": rt.Hostname, "method": rt.Method, "path": rt.Path}
+		if rt.Pass {
+			passGauge.With(labels).Set(1)
+			failGauge.With(labels).Set(0)
+		} else {
+			passGauge.With(labels).Set(0)
+			failGauge.With(labels).Set(1)
+		}
+	}
+
+	if err := push.New(gatewayURL, "gateway_contract_test").
+		Collector(passGauge).
+		Collector(failGauge).
+		Grouping("instance", "gateway-contract-test").
+		Push(); err != nil {
+		return fmt.Errorf("push to gateway %s: %w", gatewayURL, err)
+	}
+	return nil
+}
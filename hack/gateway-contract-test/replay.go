@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+)
+
+// replayEntry is one recorded gateway request/response pair to validate
+// against the resolved operation for its hostname. JSONL replay files
+// contain one of these per line; HAR files are converted into this shape.
+type replayEntry struct {
+	Hostname        string            `json:"hostname"`
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Body            json.RawMessage   `json:"body,omitempty"`
+	ResponseStatus  int               `json:"response_status,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	ResponseBody    json.RawMessage   `json:"response_body,omitempty"`
+}
+
+// loadReplayEntries reads a HAR file (by .har extension) or a JSONL file of
+// replayEntry objects, one per line.
+func loadReplayEntries(path string) ([]replayEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read replay file: %w", err)
+	}
+
+	if strings.HasSuffix(strings.ToLower(path), ".har") {
+		return parseHAR(data)
+	}
+	return parseReplayJSONL(data)
+}
+
+func parseReplayJSONL(data []byte) ([]replayEntry, error) {
+	var entries []replayEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e replayEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("parse replay line: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan replay file: %w", err)
+	}
+	return entries, nil
+}
+
+// HAR 1.2 (partial) - https://w3c.github.io/web-performance/specs/HAR/Overview.html
+type harFile struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request struct {
+		Method   string         `json:"method"`
+		URL      string         `json:"url"`
+		Headers  []harNameValue `json:"headers"`
+		PostData *struct {
+			Text string `json:"text"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status  int            `json:"status"`
+		Headers []harNameValue `json:"headers"`
+		Content struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func parseHAR(data []byte) ([]replayEntry, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("parse HAR: %w", err)
+	}
+
+	entries := make([]replayEntry, 0, len(har.Log.Entries))
+	for _, he := range har.Log.Entries {
+		e := replayEntry{
+			Hostname:        extractHostname(he.Request.URL),
+			Method:          he.Request.Method,
+			URL:             he.Request.URL,
+			Headers:         harHeaderMap(he.Request.Headers),
+			ResponseStatus:  he.Response.Status,
+			ResponseHeaders: harHeaderMap(he.Response.Headers),
+		}
+		if he.Request.PostData != nil && he.Request.PostData.Text != "" {
+			e.Body = json.RawMessage(he.Request.PostData.Text)
+		}
+		if he.Response.Content.Text != "" {
+			e.ResponseBody = json.RawMessage(he.Response.Content.Text)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func harHeaderMap(hs []harNameValue) map[string]string {
+	if len(hs) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(hs))
+	for _, h := range hs {
+		m[h.Name] = h.Value
+	}
+	return m
+}
+
+// validateReplay checks each replay entry's request against the resolved
+// OpenAPI operation for its hostname (path params, required query/header
+// params, request body schema) and, when a response was recorded, the
+// status code and response body schema too.
+func validateReplay(specs map[string]*Spec, entries []replayEntry) []routeResult {
+	ctx := context.Background()
+	results := make([]routeResult, 0, len(entries))
+
+	fail := func(e replayEntry, detail string) {
+		results = append(results, routeResult{
+			Method: e.Method, Path: e.URL, Hostname: e.Hostname,
+			Pass: false, Detail: detail,
+		})
+	}
+
+	for _, e := range entries {
+		spec, ok := specs[e.Hostname]
+		if !ok {
+			fail(e, fmt.Sprintf("no spec configured for hostname %q", e.Hostname))
+			continue
+		}
+
+		router, err := spec.ensureRouter()
+		if err != nil {
+			fail(e, err.Error())
+			continue
+		}
+
+		var bodyReader io.Reader
+		if len(e.Body) > 0 {
+			bodyReader = bytes.NewReader(e.Body)
+		}
+		req, err := http.NewRequest(strings.ToUpper(e.Method), e.URL, bodyReader)
+		if err != nil {
+			fail(e, fmt.Sprintf("build request: %v", err))
+			continue
+		}
+		for k, v := range e.Headers {
+			req.Header.Set(k, v)
+		}
+
+		route, pathParams, err := router.FindRoute(req)
+		if err != nil {
+			fail(e, fmt.Sprintf("no matching operation: %v", err))
+			continue
+		}
+
+		reqInput := &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+		}
+		if err := openapi3filter.ValidateRequest(ctx, reqInput); err != nil {
+			fail(e, fmt.Sprintf("request: %v", err))
+			continue
+		}
+
+		if e.ResponseStatus == 0 {
+			results = append(results, routeResult{
+				Method: e.Method, Path: e.URL, Hostname: e.Hostname,
+				Pass: true, Note: "request only",
+			})
+			continue
+		}
+
+		respHeader := http.Header{}
+		for k, v := range e.ResponseHeaders {
+			respHeader.Set(k, v)
+		}
+		respInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: reqInput,
+			Status:                 e.ResponseStatus,
+			Header:                 respHeader,
+		}
+		if len(e.ResponseBody) > 0 {
+			respInput.SetBodyBytes(e.ResponseBody)
+		}
+		if err := openapi3filter.ValidateResponse(ctx, respInput); err != nil {
+			fail(e, fmt.Sprintf("response: %v", err))
+			continue
+		}
+
+		results = append(results, routeResult{
+			Method: e.Method, Path: e.URL, Hostname: e.Hostname, Pass: true,
+		})
+	}
+
+	return results
+}
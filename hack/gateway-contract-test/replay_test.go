@@ -0,0 +1,97 @@
+package main
+
+import "testing"
+
+func TestParseReplayJSONL(t *testing.T) {
+	data := []byte(`{"hostname":"users.internal","method":"GET","url":"http://users.internal/users/1"}
+` + "\n" + `{"hostname":"orders.internal","method":"POST","url":"http://orders.internal/orders"}
+`)
+
+	entries, err := parseReplayJSONL(data)
+	if err != nil {
+		t.Fatalf("parseReplayJSONL: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries (blank lines skipped), got %d", len(entries))
+	}
+	if entries[0].Hostname != "users.internal" || entries[1].Method != "POST" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestParseReplayJSONLSkipsBlankLines(t *testing.T) {
+	data := []byte("\n\n" + `{"hostname":"a","method":"GET","url":"http://a/x"}` + "\n\n")
+
+	entries, err := parseReplayJSONL(data)
+	if err != nil {
+		t.Fatalf("parseReplayJSONL: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected blank lines to be skipped, got %d entries", len(entries))
+	}
+}
+
+func TestParseReplayJSONLInvalidLine(t *testing.T) {
+	if _, err := parseReplayJSONL([]byte(`not json`)); err == nil {
+		t.Fatal("expected an error for an invalid JSONL line, got nil")
+	}
+}
+
+func TestParseHAR(t *testing.T) {
+	har := []byte(`{
+		"log": {
+			"entries": [
+				{
+					"request": {
+						"method": "POST",
+						"url": "http://orders.internal/orders",
+						"headers": [{"name": "Content-Type", "value": "application/json"}],
+						"postData": {"text": "{\"id\":1}"}
+					},
+					"response": {
+						"status": 201,
+						"headers": [{"name": "X-Request-Id", "value": "abc"}],
+						"content": {"text": "{\"ok\":true}"}
+					}
+				}
+			]
+		}
+	}`)
+
+	entries, err := parseHAR(har)
+	if err != nil {
+		t.Fatalf("parseHAR: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+
+	e := entries[0]
+	if e.Hostname != "orders.internal" {
+		t.Errorf("expected hostname to be derived from the request URL, got %q", e.Hostname)
+	}
+	if e.Method != "POST" || e.ResponseStatus != 201 {
+		t.Errorf("unexpected method/status: %q/%d", e.Method, e.ResponseStatus)
+	}
+	if e.Headers["Content-Type"] != "application/json" {
+		t.Errorf("expected request headers to be carried through as a map, got %+v", e.Headers)
+	}
+	if string(e.Body) != `{"id":1}` {
+		t.Errorf("expected postData.text to become the raw request body, got %q", e.Body)
+	}
+	if string(e.ResponseBody) != `{"ok":true}` {
+		t.Errorf("expected content.text to become the raw response body, got %q", e.ResponseBody)
+	}
+}
+
+func TestParseHAREmptyPostData(t *testing.T) {
+	har := []byte(`{"log":{"entries":[{"request":{"method":"GET","url":"http://a.internal/x"},"response":{"status":200}}]}}`)
+
+	entries, err := parseHAR(har)
+	if err != nil {
+		t.Fatalf("parseHAR: %v", err)
+	}
+	if entries[0].Body != nil {
+		t.Errorf("expected no request body when postData is absent, got %q", entries[0].Body)
+	}
+}
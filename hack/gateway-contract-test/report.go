@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// routeResult is one validated route, whether it came from a route-existence
+// check against x-contract-specs or a --replay request/response validation.
+type routeResult struct {
+	Method   string
+	Path     string
+	Hostname string
+	Pass     bool
+	Detail   string // failure reason; empty when Pass is true
+	Note     string // optional extra context shown only in verbose text output
+}
+
+// uncoveredPath is a spec path that no backend route exercised, reported
+// when -warn-uncovered is set.
+type uncoveredPath struct {
+	Hostname string
+	Path     string
+}
+
+// Reporter renders a finished validation run. Implementations are selected
+// by the -output flag.
+type Reporter interface {
+	Report(routes []routeResult, uncovered []uncoveredPath) error
+}
+
+// newReporter returns the Reporter for the given -output value. configPath is
+// only used by sarifReporter, which needs a repo-relative file to anchor PR
+// annotations to.
+func newReporter(kind string, w io.Writer, configPath string, verbose bool) (Reporter, error) {
+	switch kind {
+	case "", "text":
+		return &textReporter{w: w, verbose: verbose}, nil
+	case "junit":
+		return &junitReporter{w: w}, nil
+	case "sarif":
+		return &sarifReporter{w: w, configPath: configPath}, nil
+	case "json":
+		return &jsonReporter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unknown -output %q (want text, junit, sarif, or json)", kind)
+	}
+}
+
+// textReporter reproduces the tool's original PASS/FAIL stdout stream.
+type textReporter struct {
+	w       io.Writer
+	verbose bool
+}
+
+func (r *textReporter) Report(routes []routeResult, uncovered []uncoveredPath) error {
+	passed, failed := 0, 0
+	for _, rt := range routes {
+		if rt.Pass {
+			passed++
+			if r.verbose && rt.Note != "" {
+				fmt.Fprintf(r.w, "  PASS  %-6s %-45s -> %s (%s)\n", rt.Method, rt.Path, rt.Hostname, rt.Note)
+			} else {
+				fmt.Fprintf(r.w, "  PASS  %-6s %-45s -> %s\n", rt.Method, rt.Path, rt.Hostname)
+			}
+			continue
+		}
+		failed++
+		fmt.Fprintf(r.w, "  FAIL  %-6s %-45s -> %s\n", rt.Method, rt.Path, rt.Hostname)
+		fmt.Fprintf(r.w, "        %s\n", rt.Detail)
+	}
+
+	if len(uncovered) > 0 {
+		fmt.Fprintln(r.w)
+		for _, u := range uncovered {
+			fmt.Fprintf(r.w, "  WARN  spec path %-45s in %s not covered by any gateway route\n", u.Path, u.Hostname)
+		}
+	}
+
+	fmt.Fprintln(r.w)
+	if failed > 0 {
+		fmt.Fprintf(r.w, "Result: FAIL (%d passed, %d failed)\n", passed, failed)
+		return nil
+	}
+	fmt.Fprintf(r.w, "Result: PASS (%d passed, %d failed)\n", passed, failed)
+	return nil
+}
+
+// junitReporter groups routes into one <testsuite> per hostname, one
+// <testcase> per route, so CI systems can render pass/fail trends.
+type junitReporter struct {
+	w io.Writer
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (r *junitReporter) Report(routes []routeResult, uncovered []uncoveredPath) error {
+	suiteIdx := make(map[string]int)
+	var suites junitTestSuites
+
+	for _, rt := range routes {
+		idx, ok := suiteIdx[rt.Hostname]
+		if !ok {
+			idx = len(suites.Suites)
+			suiteIdx[rt.Hostname] = idx
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: rt.Hostname})
+		}
+
+		tc := junitTestCase{Name: rt.Method + " " + rt.Path}
+		if !rt.Pass {
+			tc.Failure = &junitFailure{Message: rt.Detail, Text: rt.Detail}
+			suites.Suites[idx].Failures++
+		}
+		suites.Suites[idx].Tests++
+		suites.Suites[idx].TestCases = append(suites.Suites[idx].TestCases, tc)
+	}
+
+	out, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal JUnit XML: %w", err)
+	}
+	fmt.Fprintln(r.w, xml.Header+string(out))
+	return nil
+}
+
+// sarifReporter emits SARIF 2.1.0 so failures can be posted as PR
+// annotations by tools like github/codeql-action/upload-sarif. configPath is
+// the repo-relative krakend.json path every result's location points at,
+// since a contract mismatch is a config problem, not a line in the backend's
+// own source.
+type sarifReporter struct {
+	w          io.Writer
+	configPath string
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+func (r *sarifReporter) Report(routes []routeResult, uncovered []uncoveredPath) error {
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{Name: "gateway-contract-test", Version: "1"}},
+		}},
+	}
+
+	for _, rt := range routes {
+		if rt.Pass {
+			continue
+		}
+		log.Runs[0].Results = append(log.Runs[0].Results, sarifResult{
+			RuleID: "contract-mismatch",
+			Level:  "error",
+			Message: sarifMessage{
+				Text: fmt.Sprintf("%s %s -> %s: %s", rt.Method, rt.Path, rt.Hostname, rt.Detail),
+			},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: r.configPath},
+				},
+			}},
+		})
+	}
+
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// jsonReporter emits the raw result set for consumption by other tooling.
+type jsonReporter struct {
+	w io.Writer
+}
+
+type jsonReport struct {
+	Routes    []routeResult   `json:"routes"`
+	Uncovered []uncoveredPath `json:"uncovered,omitempty"`
+	Passed    int             `json:"passed"`
+	Failed    int             `json:"failed"`
+}
+
+func (r *jsonReporter) Report(routes []routeResult, uncovered []uncoveredPath) error {
+	report := jsonReport{Routes: routes, Uncovered: uncovered}
+	for _, rt := range routes {
+		if rt.Pass {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+	}
+
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
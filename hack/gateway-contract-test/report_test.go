@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func sampleRoutes() []routeResult {
+	return []routeResult{
+		{Method: "GET", Path: "/users/{_}", Hostname: "users.internal", Pass: true},
+		{Method: "POST", Path: "/orders", Hostname: "orders.internal", Pass: false, Detail: "not found in OpenAPI spec"},
+	}
+}
+
+func TestNewReporterUnknownKind(t *testing.T) {
+	if _, err := newReporter("yaml", &bytes.Buffer{}, "config/krakend.json", false); err == nil {
+		t.Fatal("expected an error for an unknown -output kind, got nil")
+	}
+}
+
+func TestTextReporterReportsFailure(t *testing.T) {
+	var buf bytes.Buffer
+	r := &textReporter{w: &buf}
+	if err := r.Report(sampleRoutes(), nil); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "Result: FAIL (1 passed, 1 failed)") {
+		t.Errorf("expected a FAIL summary line, got:\n%s", out)
+	}
+	if !strings.Contains(out, "PASS  GET") || !strings.Contains(out, "FAIL  POST") {
+		t.Errorf("expected both a PASS and a FAIL line, got:\n%s", out)
+	}
+}
+
+func TestJUnitReporterGroupsByHostname(t *testing.T) {
+	var buf bytes.Buffer
+	r := &junitReporter{w: &buf}
+	if err := r.Report(sampleRoutes(), nil); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("unmarshal JUnit output: %v", err)
+	}
+	if len(suites.Suites) != 2 {
+		t.Fatalf("expected one suite per hostname, got %d", len(suites.Suites))
+	}
+	for _, s := range suites.Suites {
+		if s.Name == "orders.internal" && s.Failures != 1 {
+			t.Errorf("expected orders.internal suite to have 1 failure, got %d", s.Failures)
+		}
+	}
+}
+
+func TestSarifReporterOnlyReportsFailures(t *testing.T) {
+	var buf bytes.Buffer
+	r := &sarifReporter{w: &buf, configPath: "config/krakend.json"}
+	if err := r.Report(sampleRoutes(), nil); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("unmarshal SARIF output: %v", err)
+	}
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 {
+		t.Fatalf("expected exactly one SARIF result for the one failing route, got %+v", log.Runs)
+	}
+	if uri := log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "config/krakend.json" {
+		t.Errorf("expected the SARIF location URI to point at the krakend.json config, got %q", uri)
+	}
+	if !strings.Contains(log.Runs[0].Results[0].Message.Text, "orders.internal") {
+		t.Errorf("expected the failing route's hostname to still appear in the message text, got %q", log.Runs[0].Results[0].Message.Text)
+	}
+}
+
+func TestJSONReporterCountsPassAndFail(t *testing.T) {
+	var buf bytes.Buffer
+	r := &jsonReporter{w: &buf}
+	if err := r.Report(sampleRoutes(), []uncoveredPath{{Hostname: "users.internal", Path: "/users/{_}/profile"}}); err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("unmarshal JSON output: %v", err)
+	}
+	if report.Passed != 1 || report.Failed != 1 {
+		t.Errorf("expected 1 passed and 1 failed, got passed=%d failed=%d", report.Passed, report.Failed)
+	}
+	if len(report.Uncovered) != 1 {
+		t.Errorf("expected the uncovered path to be carried through, got %+v", report.Uncovered)
+	}
+}
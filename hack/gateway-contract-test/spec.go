@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// Spec is the method+path index used for route-existence checks. doc is
+// only populated for OpenAPI 3 (and Swagger 2, converted to v3) documents;
+// it's nil for synthetic specs such as gRPC reflection or AsyncAPI, which
+// have no request/response schema to drive --replay validation against.
+type Spec struct {
+	doc      *openapi3.T
+	basePath string
+	ops      map[string]bool
+	allPaths map[string]bool
+	router   routers.Router
+}
+
+// ensureRouter lazily builds the request router used by --replay, since
+// route-existence checks never need it.
+func (s *Spec) ensureRouter() (routers.Router, error) {
+	if s.doc == nil {
+		return nil, fmt.Errorf("spec has no OpenAPI document to route against")
+	}
+	if s.router != nil {
+		return s.router, nil
+	}
+	r, err := gorillamux.NewRouter(s.doc)
+	if err != nil {
+		return nil, fmt.Errorf("build router: %w", err)
+	}
+	s.router = r
+	return r, nil
+}
+
+// newSyntheticSpec builds a Spec from a precomputed set of "METHOD path"
+// keys, for backend types (gRPC reflection, AsyncAPI) that have no OpenAPI
+// document to derive them from.
+func newSyntheticSpec(keys []string) *Spec {
+	s := &Spec{ops: make(map[string]bool, len(keys)), allPaths: make(map[string]bool, len(keys))}
+	for _, key := range keys {
+		s.ops[key] = true
+		if i := strings.IndexByte(key, ' '); i >= 0 {
+			s.allPaths[key[i+1:]] = true
+		}
+	}
+	return s
+}
+
+// opKeys returns the Spec's indexed "METHOD path" keys, sorted for a
+// deterministic on-disk cache representation.
+func (s *Spec) opKeys() []string {
+	keys := make([]string, 0, len(s.ops))
+	for k := range s.ops {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var httpMethods = map[string]bool{
+	"get": true, "post": true, "put": true, "patch": true,
+	"delete": true, "head": true, "options": true, "trace": true,
+}
+
+// buildSpec validates the document and indexes its operations by
+// "METHOD normalizedPath", mirroring the key format backendRoute uses.
+func buildSpec(doc *openapi3.T, verbose bool) (*Spec, error) {
+	ctx := context.Background()
+	if err := doc.Validate(ctx); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+
+	basePath := ""
+	if len(doc.Servers) > 0 {
+		serverURL := doc.Servers[0].URL
+		if strings.HasPrefix(serverURL, "http://") || strings.HasPrefix(serverURL, "https://") {
+			if u, err := url.Parse(serverURL); err == nil {
+				basePath = strings.TrimSuffix(u.Path, "/")
+			}
+		} else {
+			basePath = strings.TrimSuffix(serverURL, "/")
+		}
+	}
+	if verbose && basePath != "" {
+		fmt.Printf("    base path: %s\n", basePath)
+	}
+
+	s := &Spec{
+		doc:      doc,
+		basePath: basePath,
+		ops:      make(map[string]bool),
+		allPaths: make(map[string]bool),
+	}
+
+	for path, item := range doc.Paths.Map() {
+		fullPath := basePath + path
+		normalizedPath := normalizePath(fullPath)
+		s.allPaths[normalizedPath] = true
+
+		for method := range item.Operations() {
+			if !httpMethods[strings.ToLower(method)] {
+				continue
+			}
+			key := strings.ToUpper(method) + " " + normalizedPath
+			s.ops[key] = true
+			if verbose {
+				fmt.Printf("    spec: %s\n", key)
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// operationCount returns the number of indexed method+path operations.
+func (s *Spec) operationCount() int {
+	return len(s.ops)
+}
+
+// downloadAndParseSpec fetches an OpenAPI 3 document from a URL (through the
+// active spec cache, if one is set) and indexes it.
+func downloadAndParseSpec(specURL string, verbose bool) (*Spec, error) {
+	body, err := fetchURL(activeCache, specURL, verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSpec(body, verbose)
+}
+
+// loadAndParseSpec reads an OpenAPI 3 document from a local file and indexes it.
+func loadAndParseSpec(path string, verbose bool) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	return parseSpec(data, verbose)
+}
+
+// parseSpec loads raw OpenAPI 3 bytes (YAML or JSON) via kin-openapi and
+// resolves all $ref references before indexing.
+func parseSpec(data []byte, verbose bool) (*Spec, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+
+	doc, err := loader.LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse OpenAPI document: %w", err)
+	}
+
+	return buildSpec(doc, verbose)
+}
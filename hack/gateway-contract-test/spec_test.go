@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+const sampleOpenAPIDoc = `
+openapi: "3.0.0"
+info:
+  title: Users
+  version: "1"
+servers:
+  - url: http://users.internal/v1
+paths:
+  /users/{id}:
+    get:
+      operationId: getUser
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          description: ok
+  /users:
+    post:
+      operationId: createUser
+      responses:
+        "201":
+          description: created
+`
+
+func TestParseSpecIndexesOperations(t *testing.T) {
+	s, err := parseSpec([]byte(sampleOpenAPIDoc), false)
+	if err != nil {
+		t.Fatalf("parseSpec: %v", err)
+	}
+
+	if s.operationCount() != 2 {
+		t.Fatalf("expected 2 indexed operations, got %d", s.operationCount())
+	}
+
+	for _, key := range []string{"GET /v1/users/{_}", "POST /v1/users"} {
+		if !s.ops[key] {
+			t.Errorf("expected operation %q to be indexed, got %v", key, s.ops)
+		}
+	}
+}
+
+func TestParseSpecInvalidDocument(t *testing.T) {
+	if _, err := parseSpec([]byte("not an openapi document"), false); err == nil {
+		t.Fatal("expected an error for a non-OpenAPI document, got nil")
+	}
+}
+
+func TestOpKeysIsSortedAndDeterministic(t *testing.T) {
+	s, err := parseSpec([]byte(sampleOpenAPIDoc), false)
+	if err != nil {
+		t.Fatalf("parseSpec: %v", err)
+	}
+
+	keys := s.opKeys()
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keys))
+	}
+	for i := 1; i < len(keys); i++ {
+		if keys[i-1] > keys[i] {
+			t.Fatalf("expected opKeys to be sorted, got %v", keys)
+		}
+	}
+}
+
+func TestNewSyntheticSpecHasNoRouter(t *testing.T) {
+	s := newSyntheticSpec([]string{"GET /v1/items", "POST /v1/items"})
+	if s.operationCount() != 2 {
+		t.Fatalf("expected 2 operations, got %d", s.operationCount())
+	}
+	if !s.allPaths["/v1/items"] {
+		t.Errorf("expected /v1/items to be indexed as a path, got %v", s.allPaths)
+	}
+	if _, err := s.ensureRouter(); err == nil {
+		t.Fatal("expected ensureRouter to fail for a synthetic spec with no OpenAPI document")
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	yamlv3 "gopkg.in/yaml.v3"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// SpecLoader resolves a ContractSpec into a Spec, dispatching on its Type.
+type SpecLoader interface {
+	Load(ctx context.Context, spec ContractSpec, verbose bool) (*Spec, error)
+}
+
+// specLoaderFor returns the SpecLoader for a ContractSpec's type, defaulting
+// to plain OpenAPI 3 for backward compatibility with existing configs.
+func specLoaderFor(specType string) (SpecLoader, error) {
+	switch specType {
+	case "", "openapi3":
+		return openapi3Loader{}, nil
+	case "swagger2":
+		return swagger2Loader{}, nil
+	case "grpc-reflection":
+		return grpcReflectionLoader{}, nil
+	case "asyncapi":
+		return asyncapiLoader{}, nil
+	default:
+		return nil, fmt.Errorf("unknown contract spec type %q (want openapi3, swagger2, grpc-reflection, or asyncapi)", specType)
+	}
+}
+
+// openapi3Loader is the original behavior: download and index an OpenAPI 3
+// document.
+type openapi3Loader struct{}
+
+func (openapi3Loader) Load(_ context.Context, spec ContractSpec, verbose bool) (*Spec, error) {
+	return downloadAndParseSpec(spec.URL, verbose)
+}
+
+// swagger2Loader downloads a Swagger 2.0 document and converts it to
+// OpenAPI 3 before indexing, so the rest of the tool never has to know the
+// difference.
+type swagger2Loader struct{}
+
+func (swagger2Loader) Load(_ context.Context, spec ContractSpec, verbose bool) (*Spec, error) {
+	body, err := fetchURL(activeCache, spec.URL, verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc2 openapi2.T
+	if err := sigsyaml.Unmarshal(body, &doc2); err != nil {
+		return nil, fmt.Errorf("parse Swagger 2.0 document: %w", err)
+	}
+
+	doc3, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return nil, fmt.Errorf("convert Swagger 2.0 to OpenAPI 3: %w", err)
+	}
+
+	return buildSpec(doc3, verbose)
+}
+
+// grpcReflectionLoader enumerates services/methods either by connecting to
+// a live backend and using the gRPC Server Reflection protocol, or, when no
+// grpc_addr is configured, by parsing the given proto_files directly.
+type grpcReflectionLoader struct{}
+
+func (grpcReflectionLoader) Load(ctx context.Context, spec ContractSpec, verbose bool) (*Spec, error) {
+	if spec.GRPCAddr != "" {
+		return loadGRPCViaReflection(ctx, spec.GRPCAddr, verbose)
+	}
+	if len(spec.ProtoFiles) > 0 {
+		return loadGRPCViaProtoFiles(spec.ProtoFiles, verbose)
+	}
+	return nil, fmt.Errorf("grpc-reflection spec needs grpc_addr or proto_files")
+}
+
+func loadGRPCViaReflection(ctx context.Context, addr string, verbose bool) (*Spec, error) {
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	client := grpcreflect.NewClientAuto(ctx, conn)
+	defer client.Reset()
+
+	services, err := client.ListServices()
+	if err != nil {
+		return nil, fmt.Errorf("list services via reflection: %w", err)
+	}
+
+	var keys []string
+	for _, svcName := range services {
+		if strings.HasPrefix(svcName, "grpc.reflection.") {
+			continue
+		}
+		svcDesc, err := client.ResolveService(svcName)
+		if err != nil {
+			return nil, fmt.Errorf("resolve service %s: %w", svcName, err)
+		}
+		for _, m := range svcDesc.GetMethods() {
+			key := fmt.Sprintf("POST /%s/%s", svcName, m.GetName())
+			keys = append(keys, key)
+			if verbose {
+				fmt.Printf("    spec: %s\n", key)
+			}
+		}
+	}
+
+	return newSyntheticSpec(keys), nil
+}
+
+func loadGRPCViaProtoFiles(protoFiles []string, verbose bool) (*Spec, error) {
+	parser := protoparse.Parser{ImportPaths: []string{"."}}
+	fds, err := parser.ParseFiles(protoFiles...)
+	if err != nil {
+		return nil, fmt.Errorf("parse proto files: %w", err)
+	}
+
+	var keys []string
+	for _, fd := range fds {
+		for _, svc := range fd.GetServices() {
+			for _, m := range svc.GetMethods() {
+				key := fmt.Sprintf("POST /%s/%s", svc.GetFullyQualifiedName(), m.GetName())
+				keys = append(keys, key)
+				if verbose {
+					fmt.Printf("    spec: %s\n", key)
+				}
+			}
+		}
+	}
+
+	return newSyntheticSpec(keys), nil
+}
+
+// asyncapiLoader indexes an AsyncAPI document's channels as pseudo-routes
+// ("PUBLISH <channel>" / "SUBSCRIBE <channel>") so message-driven backends
+// can be contract-tested the same way as REST and gRPC ones.
+type asyncapiLoader struct{}
+
+type asyncAPIDoc struct {
+	Channels map[string]struct {
+		Publish   *struct{} `yaml:"publish"`
+		Subscribe *struct{} `yaml:"subscribe"`
+	} `yaml:"channels"`
+}
+
+func (asyncapiLoader) Load(_ context.Context, spec ContractSpec, verbose bool) (*Spec, error) {
+	body, err := fetchURL(activeCache, spec.URL, verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc asyncAPIDoc
+	if err := yamlv3.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("parse AsyncAPI document: %w", err)
+	}
+
+	var keys []string
+	for channel, ops := range doc.Channels {
+		if ops.Publish != nil {
+			keys = append(keys, "PUBLISH "+channel)
+		}
+		if ops.Subscribe != nil {
+			keys = append(keys, "SUBSCRIBE "+channel)
+		}
+		if verbose {
+			fmt.Printf("    spec: channel %s\n", channel)
+		}
+	}
+
+	return newSyntheticSpec(keys), nil
+}
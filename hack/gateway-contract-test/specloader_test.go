@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const sampleSwagger2Doc = `
+swagger: "2.0"
+info:
+  title: Items
+  version: "1"
+host: items.internal
+basePath: /v1
+schemes:
+  - http
+paths:
+  /items:
+    get:
+      operationId: listItems
+      responses:
+        "200":
+          description: ok
+`
+
+const sampleAsyncAPIDoc = `
+asyncapi: "2.6.0"
+info:
+  title: Events
+  version: "1"
+channels:
+  events/created:
+    publish: {}
+  events/updated:
+    subscribe: {}
+`
+
+func TestSpecLoaderForUnknownType(t *testing.T) {
+	if _, err := specLoaderFor("graphql"); err == nil {
+		t.Fatal("expected an error for an unknown spec type, got nil")
+	}
+}
+
+func TestSwagger2LoaderConvertsToV3(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(sampleSwagger2Doc))
+	}))
+	defer srv.Close()
+
+	s, err := (swagger2Loader{}).Load(context.Background(), ContractSpec{URL: srv.URL}, false)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !s.ops["GET /v1/items"] {
+		t.Errorf("expected the basePath-prefixed operation to be indexed, got %v", s.ops)
+	}
+}
+
+func TestAsyncAPILoaderIndexesPublishAndSubscribe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(sampleAsyncAPIDoc))
+	}))
+	defer srv.Close()
+
+	s, err := (asyncapiLoader{}).Load(context.Background(), ContractSpec{URL: srv.URL}, false)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s.operationCount() != 2 {
+		t.Fatalf("expected 2 indexed pseudo-routes, got %d: %v", s.operationCount(), s.ops)
+	}
+	if !s.ops["PUBLISH events/created"] || !s.ops["SUBSCRIBE events/updated"] {
+		t.Errorf("expected publish/subscribe channels to be indexed distinctly, got %v", s.ops)
+	}
+}